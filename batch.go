@@ -0,0 +1,85 @@
+package yamldb
+
+import "gopkg.in/yaml.v2"
+
+// batchOp is a single staged operation inside a Batch.
+type batchOp struct {
+	deleted bool
+	data    []byte
+}
+
+// Batch accumulates Write, WriteRaw and Delete operations in memory and applies them to a
+// YamlDb in a single Commit pass, mirroring the batch pattern used by Tendermint's DB layer.
+// This avoids per-call syscall overhead for callers doing bulk imports, and lets Schema
+// constraint cascades stage several file changes as one unit.
+//
+// YAML marshaling happens as each operation is staged, so marshal errors surface immediately
+// instead of midway through Commit. When the underlying YamlDb was created with
+// DiskOptions.TempDir set, Commit writes every payload to that temp directory first and only
+// then renames the files into place one after another (see applyAtomically), so a failure while
+// staging a payload leaves every key untouched rather than a half-applied batch.
+type Batch struct {
+	db    *YamlDb
+	order []string
+	ops   map[string]*batchOp
+}
+
+// NewBatch creates an empty Batch bound to db.
+func (db *YamlDb) NewBatch() *Batch {
+	return &Batch{db: db, ops: make(map[string]*batchOp)}
+}
+
+func (b *Batch) stage(key string, op *batchOp) {
+	if _, ok := b.ops[key]; !ok {
+		b.order = append(b.order, key)
+	}
+	b.ops[key] = op
+}
+
+// Write marshals s to YAML and stages it for writing on Commit.
+func (b *Batch) Write(key string, s interface{}) error {
+	data, err := yaml.Marshal(s)
+	if err != nil {
+		return err
+	}
+	return b.WriteRaw(key, data)
+}
+
+// WriteRaw stages a raw YAML resource for writing on Commit.
+func (b *Batch) WriteRaw(key string, data []byte) error {
+	b.stage(key, &batchOp{data: data})
+	return nil
+}
+
+// Delete stages a resource for removal on Commit.
+func (b *Batch) Delete(key string) error {
+	b.stage(key, &batchOp{deleted: true})
+	return nil
+}
+
+// Reset discards all staged operations without applying them.
+func (b *Batch) Reset() {
+	b.order = nil
+	b.ops = make(map[string]*batchOp)
+}
+
+// Commit applies all staged operations to the underlying YamlDb in the order they were staged.
+// Every file is written through the database's normal atomic-write path (see
+// DiskOptions.TempDir), so a crash mid-commit can only leave already-applied operations in
+// place, never a half-written file. Commit holds the same write lock a write Txn would (see
+// Txn.Commit), so no read transaction can open until the whole batch has landed. The batch is
+// reset once Commit returns, whether or not it succeeded.
+func (b *Batch) Commit() error {
+	defer b.Reset()
+
+	b.db.txnMu.Lock()
+	defer b.db.txnMu.Unlock()
+
+	ops := make([]applyOp, len(b.order))
+	for i, key := range b.order {
+		op := b.ops[key]
+		ops[i] = applyOp{key: key, deleted: op.deleted, data: op.data}
+	}
+
+	return b.db.applyAtomically(ops)
+}
@@ -0,0 +1,96 @@
+package yamldb
+
+import "testing"
+
+func TestBatch_Commit(t *testing.T) {
+	db := newYamlDb()
+	defer db.PurgeAll()
+
+	if err := db.WriteRaw("old", []byte("bar")); err != nil {
+		t.Fatal(err)
+	}
+
+	b := db.NewBatch()
+
+	if err := b.Write("foo", Mock{Amount: 123}); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.Delete("old"); err != nil {
+		t.Fatal(err)
+	}
+
+	if db.Has("foo") {
+		t.Fatal("expected staged write not to be visible before Commit")
+	}
+
+	if err := b.Commit(); err != nil {
+		t.Fatal(err)
+	}
+
+	var m Mock
+	if err := db.Read("foo", &m); err != nil {
+		t.Fatal(err)
+	}
+	if m.Amount != 123 {
+		t.FailNow()
+	}
+	if db.Has("old") {
+		t.Fatal("expected staged delete to have been applied")
+	}
+}
+
+func TestBatch_Commit_WithTempDir(t *testing.T) {
+	db := New(&DiskOptions{
+		BasePath:        basePath,
+		AppendExtension: true,
+		TempDir:         basePath + "/.tmp",
+	})
+	defer db.PurgeAll()
+
+	if err := db.WriteRaw("old", []byte("bar")); err != nil {
+		t.Fatal(err)
+	}
+
+	b := db.NewBatch()
+	if err := b.Write("foo", Mock{Amount: 123}); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.Delete("old"); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.Commit(); err != nil {
+		t.Fatal(err)
+	}
+
+	var m Mock
+	if err := db.Read("foo", &m); err != nil {
+		t.Fatal(err)
+	}
+	if m.Amount != 123 {
+		t.FailNow()
+	}
+	if db.Has("old") {
+		t.Fatal("expected staged delete to have been applied")
+	}
+}
+
+func TestBatch_Reset(t *testing.T) {
+	db := newYamlDb()
+	defer db.PurgeAll()
+
+	b := db.NewBatch()
+
+	if err := b.Write("foo", Mock{Amount: 123}); err != nil {
+		t.Fatal(err)
+	}
+
+	b.Reset()
+
+	if err := b.Commit(); err != nil {
+		t.Fatal(err)
+	}
+
+	if db.Has("foo") {
+		t.Fatal("expected reset batch not to apply the discarded write")
+	}
+}
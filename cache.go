@@ -0,0 +1,167 @@
+package yamldb
+
+import (
+	"os"
+	"sort"
+
+	"gopkg.in/yaml.v2"
+)
+
+type cacheState uint8
+
+const (
+	cacheUnchanged cacheState = iota
+	cacheSet
+	cacheDeleted
+)
+
+type cacheEntry struct {
+	state cacheState
+	data  []byte
+}
+
+// cacheSink is the surface a CacheDb buffers reads from and, on Flush, writes into. Both
+// *YamlDb and *CacheDb satisfy it, which is what lets CacheDb.CacheWrap nest cached views.
+type cacheSink interface {
+	Read(key string, out interface{}) error
+	ReadRaw(key string) ([]byte, error)
+	Has(key string) bool
+	WriteRaw(key string, data []byte) error
+	Delete(key string) error
+}
+
+// CacheDb wraps a YamlDb (or another CacheDb) and buffers Write, WriteRaw and Delete operations
+// in an in-memory map, tracking a per-key state of unchanged, set or deleted. Read and Has
+// consult the buffer first and fall through to the wrapped source on miss. This gives callers a
+// cheap way to try out a set of changes - e.g. to speculatively evaluate a Schema cascade -
+// without touching disk until they're ready to Flush.
+type CacheDb struct {
+	parent  cacheSink
+	root    *YamlDb
+	entries map[string]*cacheEntry
+	order   []string
+}
+
+// CacheWrap returns a new CacheDb buffering writes against db.
+func (db *YamlDb) CacheWrap() *CacheDb {
+	return &CacheDb{parent: db, root: db, entries: make(map[string]*cacheEntry)}
+}
+
+// CacheWrap returns a new CacheDb buffering writes against this cache, nesting a further
+// speculative view on top of it. Flushing the nested cache only applies its buffer into the
+// parent cache's buffer; the parent must be Flushed in turn to reach disk.
+func (c *CacheDb) CacheWrap() *CacheDb {
+	return &CacheDb{parent: c, root: c.root, entries: make(map[string]*cacheEntry)}
+}
+
+func (c *CacheDb) set(key string, entry *cacheEntry) {
+	if _, ok := c.entries[key]; !ok {
+		c.order = append(c.order, key)
+	}
+	c.entries[key] = entry
+}
+
+// Write marshals s to YAML and buffers it for Flush.
+func (c *CacheDb) Write(key string, s interface{}) error {
+	data, err := yaml.Marshal(s)
+	if err != nil {
+		return err
+	}
+	return c.WriteRaw(key, data)
+}
+
+// WriteRaw buffers a raw YAML resource for Flush.
+func (c *CacheDb) WriteRaw(key string, data []byte) error {
+	c.set(key, &cacheEntry{state: cacheSet, data: data})
+	return nil
+}
+
+// Delete buffers removal of a resource for Flush.
+func (c *CacheDb) Delete(key string) error {
+	c.set(key, &cacheEntry{state: cacheDeleted})
+	return nil
+}
+
+// Read unmarshals a resource into out, preferring the buffer over the wrapped source.
+func (c *CacheDb) Read(key string, out interface{}) error {
+	if entry, ok := c.entries[key]; ok {
+		if entry.state == cacheDeleted {
+			return os.ErrNotExist
+		}
+		return yaml.Unmarshal(entry.data, out)
+	}
+	return c.parent.Read(key, out)
+}
+
+// ReadRaw reads a raw YAML resource, preferring the buffer over the wrapped source.
+func (c *CacheDb) ReadRaw(key string) ([]byte, error) {
+	if entry, ok := c.entries[key]; ok {
+		if entry.state == cacheDeleted {
+			return nil, os.ErrNotExist
+		}
+		return entry.data, nil
+	}
+	return c.parent.ReadRaw(key)
+}
+
+// Has returns whether a resource exists for key, preferring the buffer over the wrapped source.
+func (c *CacheDb) Has(key string) bool {
+	if entry, ok := c.entries[key]; ok {
+		return entry.state == cacheSet
+	}
+	return c.parent.Has(key)
+}
+
+// Flush applies all buffered mutations, in deterministic key order (see
+// DiskOptions.SortOrderFunc), to the source this CacheDb wraps. When that source is the root
+// YamlDb, the mutations are applied inside a single Batch so the flush is atomic; when it's
+// another CacheDb, they're written into that cache's own buffer.
+func (c *CacheDb) Flush() error {
+	keys := append([]string(nil), c.order...)
+	less := c.root.sortOrder()
+	sort.Slice(keys, func(i, j int) bool { return less(keys[i], keys[j]) })
+
+	apply := func(write func(key string, entry *cacheEntry) error) error {
+		for _, key := range keys {
+			if err := write(key, c.entries[key]); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if db, ok := c.parent.(*YamlDb); ok {
+		b := db.NewBatch()
+		err := apply(func(key string, entry *cacheEntry) error {
+			if entry.state == cacheDeleted {
+				return b.Delete(key)
+			}
+			return b.WriteRaw(key, entry.data)
+		})
+		if err != nil {
+			return err
+		}
+		if err := b.Commit(); err != nil {
+			return err
+		}
+	} else {
+		err := apply(func(key string, entry *cacheEntry) error {
+			if entry.state == cacheDeleted {
+				return c.parent.Delete(key)
+			}
+			return c.parent.WriteRaw(key, entry.data)
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	c.Discard()
+	return nil
+}
+
+// Discard drops all buffered mutations without applying them.
+func (c *CacheDb) Discard() {
+	c.entries = make(map[string]*cacheEntry)
+	c.order = nil
+}
@@ -0,0 +1,132 @@
+package yamldb
+
+import "testing"
+
+func TestCacheDb_ReadWriteThrough(t *testing.T) {
+	db := newYamlDb()
+	defer db.PurgeAll()
+
+	if err := db.Write("foo", Mock{Amount: 1}); err != nil {
+		t.Fatal(err)
+	}
+
+	c := db.CacheWrap()
+
+	if err := c.Write("foo", Mock{Amount: 2}); err != nil {
+		t.Fatal(err)
+	}
+
+	var buffered Mock
+	if err := c.Read("foo", &buffered); err != nil {
+		t.Fatal(err)
+	}
+	if buffered.Amount != 2 {
+		t.Fatalf("expected cached read to see buffered value, got %d", buffered.Amount)
+	}
+
+	var onDisk Mock
+	if err := db.Read("foo", &onDisk); err != nil {
+		t.Fatal(err)
+	}
+	if onDisk.Amount != 1 {
+		t.Fatal("expected backing db not to see buffered write before Flush")
+	}
+}
+
+func TestCacheDb_Flush(t *testing.T) {
+	db := newYamlDb()
+	defer db.PurgeAll()
+
+	if err := db.WriteRaw("old", []byte("bar")); err != nil {
+		t.Fatal(err)
+	}
+
+	c := db.CacheWrap()
+
+	if err := c.Write("foo", Mock{Amount: 123}); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Delete("old"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := c.Flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	var m Mock
+	if err := db.Read("foo", &m); err != nil {
+		t.Fatal(err)
+	}
+	if m.Amount != 123 {
+		t.FailNow()
+	}
+	if db.Has("old") {
+		t.Fatal("expected buffered delete to have been flushed")
+	}
+}
+
+func TestCacheDb_Discard(t *testing.T) {
+	db := newYamlDb()
+	defer db.PurgeAll()
+
+	c := db.CacheWrap()
+
+	if err := c.Write("foo", Mock{Amount: 123}); err != nil {
+		t.Fatal(err)
+	}
+
+	c.Discard()
+
+	if c.Has("foo") || db.Has("foo") {
+		t.Fatal("expected discarded write not to be visible anywhere")
+	}
+}
+
+func TestCacheDb_Nested(t *testing.T) {
+	db := newYamlDb()
+	defer db.PurgeAll()
+
+	outer := db.CacheWrap()
+	if err := outer.Write("foo", Mock{Amount: 1}); err != nil {
+		t.Fatal(err)
+	}
+
+	inner := outer.CacheWrap()
+	if err := inner.Write("foo", Mock{Amount: 2}); err != nil {
+		t.Fatal(err)
+	}
+
+	var m Mock
+	if err := inner.Read("foo", &m); err != nil {
+		t.Fatal(err)
+	}
+	if m.Amount != 2 {
+		t.FailNow()
+	}
+
+	// Flushing the inner cache only lands its buffer in the outer cache; disk is untouched.
+	if err := inner.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	if db.Has("foo") {
+		t.Fatal("expected inner Flush not to reach disk directly")
+	}
+
+	if err := outer.Read("foo", &m); err != nil {
+		t.Fatal(err)
+	}
+	if m.Amount != 2 {
+		t.Fatal("expected outer cache to observe the inner cache's flushed value")
+	}
+
+	if err := outer.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Read("foo", &m); err != nil {
+		t.Fatal(err)
+	}
+	if m.Amount != 2 {
+		t.Fatal("expected outer Flush to reach disk")
+	}
+}
@@ -0,0 +1,175 @@
+package yamldb
+
+import (
+	"errors"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// IteratorClosedError is returned by Iterator methods once Close has been called.
+var IteratorClosedError = errors.New("yamldb: iterator is closed")
+
+// iteratorChunkSize is how many keys are pulled from Disk.Index at a time when SortKeys is
+// enabled, mirroring the chunking done by GetOrderedKeys.
+const iteratorChunkSize = 100
+
+// Iterator provides pull-style access to a range of keys, complementing the
+// callback-based Iterate. Unlike Iterate, the caller drives iteration one key at a time and can
+// stop early simply by calling Close, without risking a goroutine or cancel-channel leak if it
+// decides not to consume the rest of the range.
+//
+// The range is half-open: [start, end). An empty start or end means that side is unbounded.
+type Iterator struct {
+	db     *YamlDb
+	end    string
+	prefix string
+	keys   []string
+	pos    int
+	err    error
+	done   bool
+}
+
+// Iterator returns an Iterator over [start, end) in ascending key order.
+func (db *YamlDb) Iterator(start, end string) *Iterator {
+	return newIterator(db, start, end, false)
+}
+
+// ReverseIterator returns an Iterator over [start, end) in descending key order.
+func (db *YamlDb) ReverseIterator(start, end string) *Iterator {
+	return newIterator(db, start, end, true)
+}
+
+func newIterator(db *YamlDb, start, end string, reverse bool) *Iterator {
+	it := &Iterator{db: db, end: end}
+
+	if db.Disk.Index != nil {
+		it.keys = it.loadSorted(start)
+	} else {
+		it.keys = it.loadUnsorted(start)
+	}
+
+	if reverse {
+		reverseKeys(it.keys)
+	}
+
+	return it
+}
+
+// loadSorted walks Disk.Index in chunks, honoring the configured SortOrderFunc, and stops as
+// soon as a key reaches end.
+//
+// Index.Keys(start, n) doesn't give start inclusive-from semantics: if start isn't an exact
+// stored key it returns keys from the beginning of the index, and if it is a stored key that key
+// itself is skipped. So the walk starts at the beginning of the index and the lower bound is
+// filtered here, the same way loadUnsorted does it, instead of relying on Index.Keys to honor it.
+func (it *Iterator) loadSorted(start string) []string {
+	var keys []string
+	less := it.db.sortOrder()
+
+	for chunk := it.db.Disk.Index.Keys("", iteratorChunkSize); len(chunk) != 0; chunk = it.db.Disk.Index.Keys(chunk[len(chunk)-1], iteratorChunkSize+1) {
+		for _, key := range chunk {
+			if start != "" && less(key, start) {
+				continue
+			}
+			if it.end != "" && !less(key, it.end) {
+				return keys
+			}
+			keys = append(keys, key)
+		}
+	}
+
+	return keys
+}
+
+// loadUnsorted falls back to KeysPrefix when SortKeys isn't enabled, so the range is computed
+// by filtering the full keyspace and sorting the result in memory.
+func (it *Iterator) loadUnsorted(start string) []string {
+	var keys []string
+
+	cancel := make(chan struct{})
+	for key := range it.db.Disk.KeysPrefix("", cancel) {
+		if start != "" && key < start {
+			continue
+		}
+		if it.end != "" && key >= it.end {
+			continue
+		}
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
+
+func reverseKeys(keys []string) {
+	for i, j := 0, len(keys)-1; i < j; i, j = i+1, j-1 {
+		keys[i], keys[j] = keys[j], keys[i]
+	}
+}
+
+// Valid returns whether the iterator is positioned at a valid key.
+func (it *Iterator) Valid() bool {
+	return !it.done && it.err == nil && it.pos < len(it.keys)
+}
+
+// Next advances the iterator to the next key. It's a no-op when the iterator isn't Valid.
+func (it *Iterator) Next() {
+	if !it.Valid() {
+		return
+	}
+	it.pos++
+}
+
+// Key returns the key the iterator is currently positioned at, or "" if not Valid. When the
+// iterator was obtained from a PrefixDb, the prefix is stripped.
+func (it *Iterator) Key() string {
+	if !it.Valid() {
+		return ""
+	}
+	return strings.TrimPrefix(it.keys[it.pos], it.prefix)
+}
+
+// Value returns the raw YAML resource the iterator is currently positioned at, or nil if not
+// Valid. Any read error is recorded and surfaced through Error.
+func (it *Iterator) Value() []byte {
+	if !it.Valid() {
+		return nil
+	}
+
+	data, err := it.db.ReadRaw(it.keys[it.pos])
+	if err != nil {
+		it.err = err
+		return nil
+	}
+
+	return data
+}
+
+// ValueInto unmarshals the YAML resource the iterator is currently positioned at into out.
+func (it *Iterator) ValueInto(out interface{}) error {
+	if !it.Valid() {
+		return IteratorClosedError
+	}
+
+	data := it.Value()
+	if it.err != nil {
+		return it.err
+	}
+
+	return yaml.Unmarshal(data, out)
+}
+
+// Error returns the first error encountered while iterating, if any.
+func (it *Iterator) Error() error {
+	return it.err
+}
+
+// Close releases the iterator. It must be called once the caller is done iterating, and makes
+// the iterator permanently invalid.
+func (it *Iterator) Close() {
+	it.done = true
+	it.keys = nil
+}
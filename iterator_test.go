@@ -0,0 +1,130 @@
+package yamldb
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestIterator(t *testing.T) {
+	db := New(&DiskOptions{
+		BasePath:        basePath,
+		AppendExtension: false,
+	})
+	defer db.PurgeAll()
+
+	for _, key := range []string{"a", "b", "c", "d"} {
+		if err := db.WriteRaw(key, []byte(key)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var keys []string
+	it := db.Iterator("b", "d")
+	defer it.Close()
+
+	for ; it.Valid(); it.Next() {
+		keys = append(keys, it.Key())
+	}
+
+	if err := it.Error(); err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(keys, []string{"b", "c"}) {
+		t.Fatalf("expected [b c] but got %v", keys)
+	}
+}
+
+func TestIterator_Sorted(t *testing.T) {
+	db := New(&DiskOptions{
+		BasePath:        basePath,
+		AppendExtension: false,
+		SortKeys:        true,
+	})
+	defer db.PurgeAll()
+
+	for _, key := range []string{"d", "b", "a", "c"} {
+		if err := db.WriteRaw(key, []byte(key)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var keys []string
+	it := db.Iterator("", "")
+	defer it.Close()
+
+	for ; it.Valid(); it.Next() {
+		keys = append(keys, it.Key())
+	}
+
+	if !reflect.DeepEqual(keys, []string{"a", "b", "c", "d"}) {
+		t.Fatalf("expected sorted keys but got %v", keys)
+	}
+}
+
+func TestReverseIterator(t *testing.T) {
+	db := New(&DiskOptions{
+		BasePath:        basePath,
+		AppendExtension: false,
+		SortKeys:        true,
+	})
+	defer db.PurgeAll()
+
+	for _, key := range []string{"a", "b", "c"} {
+		if err := db.WriteRaw(key, []byte(key)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var keys []string
+	it := db.ReverseIterator("", "")
+	defer it.Close()
+
+	for ; it.Valid(); it.Next() {
+		keys = append(keys, it.Key())
+	}
+
+	if !reflect.DeepEqual(keys, []string{"c", "b", "a"}) {
+		t.Fatalf("expected [c b a] but got %v", keys)
+	}
+}
+
+func TestIterator_ValueInto(t *testing.T) {
+	db := newYamlDb()
+	defer db.PurgeAll()
+
+	if err := db.Write("foo", Mock{Amount: 123}); err != nil {
+		t.Fatal(err)
+	}
+
+	it := db.Iterator("", "")
+	defer it.Close()
+
+	if !it.Valid() {
+		t.FailNow()
+	}
+
+	var m Mock
+	if err := it.ValueInto(&m); err != nil {
+		t.Fatal(err)
+	}
+	if m.Amount != 123 {
+		t.FailNow()
+	}
+}
+
+func TestIterator_Close(t *testing.T) {
+	db := newYamlDb()
+	defer db.PurgeAll()
+
+	if err := db.WriteRaw("foo", []byte("bar")); err != nil {
+		t.Fatal(err)
+	}
+
+	it := db.Iterator("", "")
+	it.Close()
+
+	if it.Valid() {
+		t.Fatal("expected closed iterator to be invalid")
+	}
+}
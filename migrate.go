@@ -0,0 +1,119 @@
+package yamldb
+
+import (
+	"path"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// MigrationFunc transforms a schema file's decoded YAML data from one Schema.Version to the
+// next. It operates on a generic map rather than a concrete struct so that struct field
+// renames/removals between versions don't break decoding of old files.
+type MigrationFunc func(raw map[string]interface{}) (map[string]interface{}, error)
+
+// migrationMarker is written to a schema type's "_migrations" marker file to record the highest
+// Schema.Version reached by Migrate.
+type migrationMarker struct {
+	// Applied maps a schema type to the highest Schema.Version migrated to so far.
+	Applied map[string]int
+}
+
+const migrationMarkerFile = "_migrations"
+
+// RegisterMigration registers fn to migrate a schema file of the given schemaType away from
+// fromVersion to fromVersion+1. schemaType also identifies the prefix later passed to Migrate.
+func (db *YamlDb) RegisterMigration(schemaType string, fromVersion int, fn MigrationFunc) {
+	if db.migrations == nil {
+		db.migrations = make(map[string]map[int]MigrationFunc)
+	}
+	if db.migrations[schemaType] == nil {
+		db.migrations[schemaType] = make(map[int]MigrationFunc)
+	}
+	db.migrations[schemaType][fromVersion] = fn
+}
+
+// Migrate reads every schema file under prefix, and for each one applies registered migrations
+// (see RegisterMigration, registered under schemaType == prefix) in ascending order, starting
+// from the file's own Schema.Version, until no further migration is registered for its current
+// version. All writes are applied through a single write transaction, so a failure partway
+// through the run is rolled back, and a marker file recording the highest version reached is
+// written alongside the data. Which migrations run is decided by each file's own version, so
+// re-running Migrate applies no migration twice; every file under prefix is still rewritten.
+func (db *YamlDb) Migrate(prefix string) error {
+	migrations := db.migrations[prefix]
+	if len(migrations) == 0 {
+		return nil
+	}
+
+	markerKey := path.Join(prefix, migrationMarkerFile)
+
+	tx := db.NewWriteTxn()
+
+	var marker migrationMarker
+	if tx.Has(markerKey) {
+		if err := tx.Read(markerKey, &marker); err != nil {
+			tx.Abort()
+			return err
+		}
+	}
+	if marker.Applied == nil {
+		marker.Applied = make(map[string]int)
+	}
+	highest := marker.Applied[prefix]
+
+	_, err := db.Iterate(prefix, func(key string, data []byte) error {
+		// Iterate yields extension-suffixed keys (e.g. "users/_migrations.yaml") when
+		// AppendExtension is set, so compare against markerKey with the suffix stripped rather
+		// than matching it verbatim.
+		if strings.TrimSuffix(key, extension) == markerKey {
+			return nil
+		}
+
+		var raw map[string]interface{}
+		if err := yaml.Unmarshal(data, &raw); err != nil {
+			return err
+		}
+
+		version, _ := raw["version"].(int)
+
+		for {
+			fn, registered := migrations[version]
+			if !registered {
+				break
+			}
+
+			migrated, err := fn(raw)
+			if err != nil {
+				return err
+			}
+			raw = migrated
+
+			version++
+			raw["version"] = version
+		}
+
+		if version > highest {
+			highest = version
+		}
+
+		out, err := yaml.Marshal(raw)
+		if err != nil {
+			return err
+		}
+
+		return tx.WriteRaw(key, out)
+	})
+	if err != nil {
+		tx.Abort()
+		return err
+	}
+
+	marker.Applied[prefix] = highest
+	if err := tx.Write(markerKey, &marker); err != nil {
+		tx.Abort()
+		return err
+	}
+
+	return tx.Commit()
+}
@@ -0,0 +1,78 @@
+package yamldb
+
+import "testing"
+
+func TestYamlDb_Migrate(t *testing.T) {
+	db := newYamlDb()
+	defer db.PurgeAll()
+
+	if err := db.Write("users/1", Schema{
+		Version: 0,
+		Key:     "users/1",
+		Data: User{
+			Id:   1,
+			Name: "David",
+		},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	db.RegisterMigration("users", 0, func(raw map[string]interface{}) (map[string]interface{}, error) {
+		data, ok := raw["data"].(map[interface{}]interface{})
+		if !ok {
+			t.Fatalf("expected data to decode as a map, got %T", raw["data"])
+		}
+		data["fullname"] = data["name"]
+		delete(data, "name")
+		return raw, nil
+	})
+
+	if err := db.Migrate("users"); err != nil {
+		t.Fatal(err)
+	}
+
+	var migrated Schema
+	if err := db.Read("users/1", &migrated); err != nil {
+		t.Fatal(err)
+	}
+	if migrated.Version != 1 {
+		t.Fatalf("expected migrated version to be 1, got %d", migrated.Version)
+	}
+
+	data, ok := migrated.Data.(map[interface{}]interface{})
+	if !ok {
+		t.Fatalf("expected Data to decode as a map, got %T", migrated.Data)
+	}
+	if data["fullname"] != "David" {
+		t.Fatalf("expected fullname to be David, got %v", data["fullname"])
+	}
+	if _, hasOldField := data["name"]; hasOldField {
+		t.Fatal("expected the old name field to have been removed by the migration")
+	}
+}
+
+func TestYamlDb_Migrate_Idempotent(t *testing.T) {
+	db := newYamlDb()
+	defer db.PurgeAll()
+
+	if err := db.Write("users/1", Schema{Version: 0, Key: "users/1", Data: User{Id: 1, Name: "David"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	var calls int
+	db.RegisterMigration("users", 0, func(raw map[string]interface{}) (map[string]interface{}, error) {
+		calls++
+		return raw, nil
+	})
+
+	if err := db.Migrate("users"); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Migrate("users"); err != nil {
+		t.Fatal(err)
+	}
+
+	if calls != 1 {
+		t.Fatalf("expected the migration to run exactly once across both Migrate calls, ran %d times", calls)
+	}
+}
@@ -0,0 +1,253 @@
+package yamldb
+
+import "strings"
+
+// PrefixDb scopes a YamlDb to a subtree, transparently prefixing every key on the way in and
+// stripping the prefix off keys on the way out. It exposes the same surface as YamlDb so it can
+// be handed to a sub-package (e.g. "give the posts module a db rooted at posts/") without that
+// package seeing, or accidentally touching, the rest of the keyspace.
+type PrefixDb struct {
+	db     *YamlDb
+	prefix string
+}
+
+// WithPrefix returns a PrefixDb scoped to keys under prefix.
+func (db *YamlDb) WithPrefix(prefix string) *PrefixDb {
+	return &PrefixDb{db: db, prefix: prefix}
+}
+
+func (p *PrefixDb) key(key string) string {
+	return p.prefix + key
+}
+
+func (p *PrefixDb) unkey(key string) string {
+	return strings.TrimPrefix(key, p.prefix)
+}
+
+// endKey translates an (exclusive) end bound into the underlying keyspace, so that an unbounded
+// end ("") stays scoped to this prefix's subtree instead of spilling into the rest of the
+// database.
+func (p *PrefixDb) endKey(end string) string {
+	if end != "" {
+		return p.key(end)
+	}
+	return prefixUpperBound(p.prefix)
+}
+
+// prefixUpperBound returns the lexicographically smallest key that's exclusively greater than
+// every key starting with prefix, or "" if prefix has no upper bound (e.g. it's empty or all
+// 0xff bytes).
+func prefixUpperBound(prefix string) string {
+	bs := []byte(prefix)
+	for i := len(bs) - 1; i >= 0; i-- {
+		bs[i]++
+		if bs[i] != 0 {
+			return string(bs[:i+1])
+		}
+	}
+	return ""
+}
+
+// Write writes a YAML-serializable struct to the database, scoped to this prefix.
+func (p *PrefixDb) Write(key string, s interface{}) error {
+	return p.db.Write(p.key(key), s)
+}
+
+// WriteRaw writes a raw YAML resource to the database, scoped to this prefix.
+func (p *PrefixDb) WriteRaw(key string, data []byte) error {
+	return p.db.WriteRaw(p.key(key), data)
+}
+
+// Read unmarshals a resource from the database into given struct, scoped to this prefix.
+func (p *PrefixDb) Read(key string, out interface{}) error {
+	return p.db.Read(p.key(key), out)
+}
+
+// ReadStrict strictly unmarshals a resource from the database into given struct, scoped to this
+// prefix.
+func (p *PrefixDb) ReadStrict(key string, out interface{}) error {
+	return p.db.ReadStrict(p.key(key), out)
+}
+
+// ReadRaw reads a raw YAML resource from the database, scoped to this prefix.
+func (p *PrefixDb) ReadRaw(key string) ([]byte, error) {
+	return p.db.ReadRaw(p.key(key))
+}
+
+// Delete removes a resource from the database, scoped to this prefix.
+func (p *PrefixDb) Delete(key string) error {
+	return p.db.Delete(p.key(key))
+}
+
+// Update updates a resource from the database with values from provided update function, scoped
+// to this prefix.
+func (p *PrefixDb) Update(key string, t interface{}, updateFunc func(s interface{})) error {
+	return p.db.Update(p.key(key), t, updateFunc)
+}
+
+// Has returns whether the database stores a value for provided key, scoped to this prefix.
+func (p *PrefixDb) Has(key string) bool {
+	return p.db.Has(p.key(key))
+}
+
+// Purge removes all resources under this prefix whose key also starts with given prefix.
+// This action cannot be undone!
+func (p *PrefixDb) Purge(prefix string) error {
+	return p.db.Purge(p.key(prefix))
+}
+
+// Iterate iterates over each YAML resource under this prefix whose key also starts with given
+// prefix. Keys passed to callback have this PrefixDb's prefix stripped.
+func (p *PrefixDb) Iterate(prefix string, callback func(key string, data []byte) error) (bool, error) {
+	return p.db.Iterate(p.key(prefix), func(key string, data []byte) error {
+		return callback(p.unkey(key), data)
+	})
+}
+
+// IterateSerialized iterates over each resource under this prefix, serializes it and passes it
+// through specified callback for processing.
+func (p *PrefixDb) IterateSerialized(prefix string, out interface{}, callback func(s interface{}) error) (bool, error) {
+	return p.db.IterateSerialized(p.key(prefix), out, callback)
+}
+
+// GetOrderedKeys returns all keys under this prefix - or those also starting with given prefix -
+// in order when SortKeys is enabled. Keys are returned with this PrefixDb's prefix stripped.
+func (p *PrefixDb) GetOrderedKeys(prefix string, from string, chunks int) []string {
+	var fromKey string
+	if from != "" {
+		fromKey = p.key(from)
+	}
+
+	keys := p.db.GetOrderedKeys(p.key(prefix), fromKey, chunks)
+
+	unkeyed := make([]string, len(keys))
+	for i, key := range keys {
+		unkeyed[i] = p.unkey(key)
+	}
+	return unkeyed
+}
+
+// Iterator returns an Iterator over [start, end) under this prefix, in ascending key order. Keys
+// returned by Key have this PrefixDb's prefix stripped.
+func (p *PrefixDb) Iterator(start, end string) *Iterator {
+	it := p.db.Iterator(p.key(start), p.endKey(end))
+	it.prefix = p.prefix
+	return it
+}
+
+// ReverseIterator returns an Iterator over [start, end) under this prefix, in descending key
+// order. Keys returned by Key have this PrefixDb's prefix stripped.
+func (p *PrefixDb) ReverseIterator(start, end string) *Iterator {
+	it := p.db.ReverseIterator(p.key(start), p.endKey(end))
+	it.prefix = p.prefix
+	return it
+}
+
+// PrefixBatch is a Batch scoped to a PrefixDb's subtree.
+type PrefixBatch struct {
+	b      *Batch
+	prefix string
+}
+
+// NewBatch returns a Batch scoped to this prefix.
+func (p *PrefixDb) NewBatch() *PrefixBatch {
+	return &PrefixBatch{b: p.db.NewBatch(), prefix: p.prefix}
+}
+
+// Write marshals s to YAML and stages it for writing on Commit, scoped to this prefix.
+func (pb *PrefixBatch) Write(key string, s interface{}) error {
+	return pb.b.Write(pb.prefix+key, s)
+}
+
+// WriteRaw stages a raw YAML resource for writing on Commit, scoped to this prefix.
+func (pb *PrefixBatch) WriteRaw(key string, data []byte) error {
+	return pb.b.WriteRaw(pb.prefix+key, data)
+}
+
+// Delete stages a resource for removal on Commit, scoped to this prefix.
+func (pb *PrefixBatch) Delete(key string) error {
+	return pb.b.Delete(pb.prefix + key)
+}
+
+// Reset discards all staged operations without applying them.
+func (pb *PrefixBatch) Reset() {
+	pb.b.Reset()
+}
+
+// Commit applies all staged operations in the order they were staged.
+func (pb *PrefixBatch) Commit() error {
+	return pb.b.Commit()
+}
+
+// PrefixTxn is a Txn scoped to a PrefixDb's subtree.
+type PrefixTxn struct {
+	tx     *Txn
+	prefix string
+}
+
+// NewReadTxn opens a read-only transaction scoped to this prefix.
+func (p *PrefixDb) NewReadTxn() *PrefixTxn {
+	return &PrefixTxn{tx: p.db.NewReadTxn(), prefix: p.prefix}
+}
+
+// NewWriteTxn opens a write transaction scoped to this prefix.
+func (p *PrefixDb) NewWriteTxn() *PrefixTxn {
+	return &PrefixTxn{tx: p.db.NewWriteTxn(), prefix: p.prefix}
+}
+
+// Write stages a YAML-serializable struct to be written to the database on Commit, scoped to
+// this prefix.
+func (pt *PrefixTxn) Write(key string, s interface{}) error {
+	return pt.tx.Write(pt.prefix+key, s)
+}
+
+// WriteRaw stages a raw YAML resource to be written to the database on Commit, scoped to this
+// prefix.
+func (pt *PrefixTxn) WriteRaw(key string, data []byte) error {
+	return pt.tx.WriteRaw(pt.prefix+key, data)
+}
+
+// Delete stages a resource to be removed from the database on Commit, scoped to this prefix.
+func (pt *PrefixTxn) Delete(key string) error {
+	return pt.tx.Delete(pt.prefix + key)
+}
+
+// Purge stages removal of all resources under this prefix that also start with given prefix.
+func (pt *PrefixTxn) Purge(prefix string) error {
+	return pt.tx.Purge(pt.prefix + prefix)
+}
+
+// Update stages an update to a resource, scoped to this prefix.
+func (pt *PrefixTxn) Update(key string, t interface{}, updateFunc func(s interface{})) error {
+	return pt.tx.Update(pt.prefix+key, t, updateFunc)
+}
+
+// Read unmarshals a resource into given struct, scoped to this prefix.
+func (pt *PrefixTxn) Read(key string, out interface{}) error {
+	return pt.tx.Read(pt.prefix+key, out)
+}
+
+// ReadStrict strictly unmarshals a resource into given struct, scoped to this prefix.
+func (pt *PrefixTxn) ReadStrict(key string, out interface{}) error {
+	return pt.tx.ReadStrict(pt.prefix+key, out)
+}
+
+// ReadRaw reads a raw YAML resource, scoped to this prefix.
+func (pt *PrefixTxn) ReadRaw(key string) ([]byte, error) {
+	return pt.tx.ReadRaw(pt.prefix + key)
+}
+
+// Has returns whether the database stores a value for provided key, scoped to this prefix.
+func (pt *PrefixTxn) Has(key string) bool {
+	return pt.tx.Has(pt.prefix + key)
+}
+
+// Commit applies all pending writes and deletes and releases the transaction's lock.
+func (pt *PrefixTxn) Commit() error {
+	return pt.tx.Commit()
+}
+
+// Abort discards all pending mutations and releases the transaction's lock.
+func (pt *PrefixTxn) Abort() {
+	pt.tx.Abort()
+}
@@ -0,0 +1,190 @@
+package yamldb
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestPrefixDb_ReadWrite(t *testing.T) {
+	db := newYamlDb()
+	defer db.PurgeAll()
+
+	users := db.WithPrefix("users/")
+
+	if err := users.Write("1", Mock{Amount: 1}); err != nil {
+		t.Fatal(err)
+	}
+
+	if !db.Has("users/1") {
+		t.Fatal("expected write to land under the configured prefix in the backing db")
+	}
+
+	var m Mock
+	if err := users.Read("1", &m); err != nil {
+		t.Fatal(err)
+	}
+	if m.Amount != 1 {
+		t.FailNow()
+	}
+
+	if !users.Has("1") {
+		t.FailNow()
+	}
+
+	if err := users.Delete("1"); err != nil {
+		t.Fatal(err)
+	}
+	if db.Has("users/1") {
+		t.Fatal("expected delete to remove the prefixed key from the backing db")
+	}
+}
+
+func TestPrefixDb_Isolation(t *testing.T) {
+	db := newYamlDb()
+	defer db.PurgeAll()
+
+	users := db.WithPrefix("users/")
+	posts := db.WithPrefix("posts/")
+
+	if err := users.Write("1", Mock{Amount: 1}); err != nil {
+		t.Fatal(err)
+	}
+
+	if posts.Has("1") {
+		t.Fatal("expected posts prefix not to see users prefix's keys")
+	}
+	if !users.Has("1") {
+		t.FailNow()
+	}
+}
+
+func TestPrefixDb_Iterate(t *testing.T) {
+	db := newYamlDb()
+	defer db.PurgeAll()
+
+	users := db.WithPrefix("users/")
+
+	for _, key := range []string{"1", "2", "3"} {
+		if err := users.WriteRaw(key, []byte(key)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := db.WriteRaw("posts/1", []byte("x")); err != nil {
+		t.Fatal(err)
+	}
+
+	var keys []string
+	ok, err := users.Iterate("", func(key string, data []byte) error {
+		keys = append(keys, key)
+		return nil
+	})
+	if err != nil || !ok {
+		t.Fatal(err)
+	}
+
+	if len(keys) != 3 {
+		t.Fatalf("expected 3 keys scoped to the prefix, got %v", keys)
+	}
+	for _, key := range keys {
+		if key == "posts/1" {
+			t.Fatal("expected posts key not to leak into users iteration")
+		}
+	}
+}
+
+func TestPrefixDb_GetOrderedKeys(t *testing.T) {
+	db := New(&DiskOptions{
+		BasePath:        basePath,
+		AppendExtension: false,
+		SortKeys:        true,
+	})
+	defer db.PurgeAll()
+
+	users := db.WithPrefix("users/")
+
+	for _, key := range []string{"c", "a", "b"} {
+		if err := users.WriteRaw(key, []byte(key)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := db.WriteRaw("posts/a", []byte("x")); err != nil {
+		t.Fatal(err)
+	}
+
+	actual := users.GetOrderedKeys("", "", 10)
+	expected := []string{"a", "b", "c"}
+
+	if !reflect.DeepEqual(actual, expected) {
+		t.Fatalf("expected %v but got %v", expected, actual)
+	}
+}
+
+func TestPrefixDb_Iterator(t *testing.T) {
+	db := New(&DiskOptions{
+		BasePath:        basePath,
+		AppendExtension: false,
+		SortKeys:        true,
+	})
+	defer db.PurgeAll()
+
+	users := db.WithPrefix("users/")
+
+	for _, key := range []string{"a", "b", "c"} {
+		if err := users.WriteRaw(key, []byte(key)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := db.WriteRaw("postsZZZ", []byte("x")); err != nil {
+		t.Fatal(err)
+	}
+
+	var keys []string
+	it := users.Iterator("", "")
+	defer it.Close()
+
+	for ; it.Valid(); it.Next() {
+		keys = append(keys, it.Key())
+	}
+
+	if !reflect.DeepEqual(keys, []string{"a", "b", "c"}) {
+		t.Fatalf("expected [a b c] but got %v", keys)
+	}
+}
+
+func TestPrefixDb_Txn(t *testing.T) {
+	db := newYamlDb()
+	defer db.PurgeAll()
+
+	users := db.WithPrefix("users/")
+
+	tx := users.NewWriteTxn()
+	if err := tx.Write("1", Mock{Amount: 1}); err != nil {
+		t.Fatal(err)
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatal(err)
+	}
+
+	if !db.Has("users/1") {
+		t.Fatal("expected committed txn write to land under the configured prefix")
+	}
+}
+
+func TestPrefixDb_Batch(t *testing.T) {
+	db := newYamlDb()
+	defer db.PurgeAll()
+
+	users := db.WithPrefix("users/")
+
+	b := users.NewBatch()
+	if err := b.Write("1", Mock{Amount: 1}); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.Commit(); err != nil {
+		t.Fatal(err)
+	}
+
+	if !db.Has("users/1") {
+		t.Fatal("expected committed batch write to land under the configured prefix")
+	}
+}
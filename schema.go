@@ -57,15 +57,22 @@ type Schema struct {
 }
 
 // Delete deletes the schema and file on disk while also triggering any set constraints.
+//
+// The schema itself and any cascaded references are deleted inside a single write transaction,
+// so a failure partway through leaves the previous state intact.
 func (s *Schema) Delete(db *YamlDb) error {
+	tx := db.NewWriteTxn()
+
 	for _, ref := range s.References {
-		if db.Has(ref.Key) {
+		if tx.Has(ref.Key) {
 			switch ref.Constraints.Delete {
 			case Cascade:
-				if err := db.Delete(ref.Key); err != nil {
+				if err := tx.Delete(ref.Key); err != nil {
+					tx.Abort()
 					return err
 				}
 			case Restrict:
+				tx.Abort()
 				return DeleteRestrictedError
 			case NoAction:
 			default:
@@ -73,44 +80,46 @@ func (s *Schema) Delete(db *YamlDb) error {
 			}
 		}
 
-		if db.Has(s.Key) {
-			if err := db.Delete(s.Key); err != nil {
+		if tx.Has(s.Key) {
+			if err := tx.Delete(s.Key); err != nil {
+				tx.Abort()
 				return err
 			}
 		}
 	}
 
-	return nil
+	return tx.Commit()
 }
 
-// Update changes this and referenced schema key while also triggering any set constraints.
+// Update renames this schema's key and cascades the rename through its reference graph: any
+// Cascade reference has its foreign-key field (see rewriteForeignKey) and reverse-direction
+// SchemaReference.Key rewritten from the old key to updatedKey, recursively. The rename itself
+// is performed by writing the schema under updatedKey and deleting the old file.
+//
+// Everything runs inside a single write transaction, so a failure partway through the cascade
+// leaves the previous state intact.
 func (s *Schema) Update(db *YamlDb, updatedKey string, onUpdate func(schema *Schema)) error {
-	for _, ref := range s.References {
-		if !db.Has(ref.Key) {
-			return fmt.Errorf("old reference to %s not found", ref.Key)
-		}
-		switch ref.Constraints.Update {
-		case Cascade:
-			// TODO: link back to referenced table somehow (probably gonna need reflection) and actually update the 'foreign key' to the new key
-			fallthrough
-		case Restrict:
-			return UpdateRestrictedError
-		case NoAction:
-		default:
-			break
-		}
+	oldKey := s.Key
+	tx := db.NewWriteTxn()
 
-		err := db.Update(s.Key, s, func(i interface{}) {
-			schema := i.(*Schema)
-			schema.Key = updatedKey
-			if onUpdate != nil {
-				onUpdate(schema)
-			}
-		})
-		if err != nil {
-			return err
-		}
+	if err := s.cascadeUpdate(tx, oldKey, updatedKey, map[string]bool{oldKey: true}); err != nil {
+		tx.Abort()
+		return err
+	}
+
+	s.Key = updatedKey
+	if onUpdate != nil {
+		onUpdate(s)
+	}
+
+	if err := tx.Write(updatedKey, s); err != nil {
+		tx.Abort()
+		return err
+	}
+	if err := tx.Delete(oldKey); err != nil {
+		tx.Abort()
+		return err
 	}
 
-	return nil
+	return tx.Commit()
 }
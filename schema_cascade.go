@@ -0,0 +1,120 @@
+package yamldb
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// ErrCircularReference is returned by Schema.Update when cascading a key rename through the
+// reference graph loops back to a Schema it has already visited.
+var ErrCircularReference = errors.New("yamldb: circular reference detected during cascade")
+
+// ForeignKey is the well-known field name rewriteForeignKey matches in a Schema's Data, alongside
+// any field tagged `yamldb:"fk=..."`.
+const ForeignKey = "ForeignKey"
+
+const foreignKeyTagPrefix = "fk="
+
+// cascadeUpdate walks s.References, looking for Cascade constraints that must follow the
+// rename from oldKey to newKey. For each one it loads the referenced Schema, rewrites its
+// foreign-key field and any reverse-direction SchemaReference.Key pointing at oldKey, then
+// recurses so that multi-hop chains (A -> B -> C) stay consistent. visited is keyed on Schema
+// key and guards against cycles in the reference graph.
+func (s *Schema) cascadeUpdate(tx *Txn, oldKey, newKey string, visited map[string]bool) error {
+	for _, ref := range s.References {
+		if !tx.Has(ref.Key) {
+			return fmt.Errorf("old reference to %s not found", ref.Key)
+		}
+
+		switch ref.Constraints.Update {
+		case Cascade:
+			if visited[ref.Key] {
+				return ErrCircularReference
+			}
+			visited[ref.Key] = true
+
+			var refSchema Schema
+			if err := tx.Read(ref.Key, &refSchema); err != nil {
+				return err
+			}
+
+			if err := refSchema.cascadeUpdate(tx, oldKey, newKey, visited); err != nil {
+				return err
+			}
+
+			rewriteForeignKey(refSchema.Data, oldKey, newKey)
+			rewriteReverseReferences(refSchema.References, oldKey, newKey)
+
+			if err := tx.Write(ref.Key, &refSchema); err != nil {
+				return err
+			}
+		case Restrict:
+			return UpdateRestrictedError
+		case NoAction:
+		default:
+			break
+		}
+	}
+
+	return nil
+}
+
+// rewriteForeignKey rewrites any occurrence of oldKey to newKey on a Schema's Data, in a field
+// tagged `yamldb:"fk=..."` or named ForeignKey. Data is usually decoded from YAML into a
+// map[interface{}]interface{} (since Schema.Data is untyped), but a pointer to a concrete struct
+// with yamldb tags is also supported; a struct held by value is left unchanged.
+func rewriteForeignKey(data interface{}, oldKey, newKey string) {
+	v := reflect.ValueOf(data)
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return
+		}
+		v = v.Elem()
+	}
+
+	switch v.Kind() {
+	case reflect.Struct:
+		t := v.Type()
+		for i := 0; i < v.NumField(); i++ {
+			if !isForeignKeyField(t.Field(i)) {
+				continue
+			}
+			fv := v.Field(i)
+			if fv.Kind() == reflect.String && fv.CanSet() && fv.String() == oldKey {
+				fv.SetString(newKey)
+			}
+		}
+	case reflect.Map:
+		// Data decoded off disk has no struct tags to consult, so fall back to a
+		// case-insensitive match against ForeignKey: yaml.v2 lowercases untagged field names
+		// on marshal, so a Go field named ForeignKey round-trips as a "foreignkey" map key.
+		for _, key := range v.MapKeys() {
+			if !strings.EqualFold(fmt.Sprint(key.Interface()), ForeignKey) {
+				continue
+			}
+			if s, ok := v.MapIndex(key).Interface().(string); ok && s == oldKey {
+				v.SetMapIndex(key, reflect.ValueOf(newKey))
+			}
+		}
+	}
+}
+
+func isForeignKeyField(field reflect.StructField) bool {
+	if field.Name == ForeignKey {
+		return true
+	}
+	return strings.HasPrefix(field.Tag.Get("yamldb"), foreignKeyTagPrefix)
+}
+
+// rewriteReverseReferences rewrites any SchemaReference.Key pointing at oldKey to newKey, so
+// that a Schema referenced by a cascaded rename keeps pointing at the right key in the other
+// direction too.
+func rewriteReverseReferences(refs []*SchemaReference, oldKey, newKey string) {
+	for _, ref := range refs {
+		if ref.Key == oldKey {
+			ref.Key = newKey
+		}
+	}
+}
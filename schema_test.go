@@ -12,8 +12,9 @@ type User struct {
 
 // Only one Post can belong to one User.
 type Post struct {
-	Id      int
-	Message string
+	Id         int
+	Message    string
+	ForeignKey string
 }
 
 const (
@@ -30,11 +31,13 @@ func getUserSchema() (Schema, *YamlDb, error) {
 	// write user schema
 	err := db.Write(userKey, Schema{
 		Key: userKey,
-		Reference: &SchemaReference{
-			Key: postKey, // references the post below
-			Constraints: Constraints{
-				Delete: Cascade,
-				Update: Cascade,
+		References: []*SchemaReference{
+			{
+				Key: postKey, // references the post below
+				Constraints: Constraints{
+					Delete: Cascade,
+					Update: Cascade,
+				},
 			},
 		},
 		Data: User{
@@ -50,10 +53,14 @@ func getUserSchema() (Schema, *YamlDb, error) {
 	err = db.Write(postKey, Schema{
 		Key: postKey,
 		Data: Post{
-			Id:      1,
-			Message: "Hello World!",
+			Id:         1,
+			Message:    "Hello World!",
+			ForeignKey: userKey,
 		},
 	})
+	if err != nil {
+		return Schema{}, nil, err
+	}
 
 	// unserialize user schema
 	var schema Schema
@@ -82,16 +89,181 @@ func TestSchema_Delete(t *testing.T) {
 	}
 }
 
-func TestSchema_Update(t *testing.T) {
+func TestSchema_Update_Cascade(t *testing.T) {
+	schema, db, err := getUserSchema()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.PurgeAll()
+
+	const updatedKey = "users/2"
+
+	if err = schema.Update(db, updatedKey, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	if db.Has(userKey) {
+		t.Fatal("expected old user key to have been removed")
+	}
+	if !db.Has(updatedKey) {
+		t.Fatal("expected user to have been written under the new key")
+	}
+
+	var post Schema
+	if err = db.Read(postKey, &post); err != nil {
+		t.Fatal(err)
+	}
+
+	data, ok := post.Data.(map[interface{}]interface{})
+	if !ok {
+		t.Fatalf("expected post Data to decode as a map, got %T", post.Data)
+	}
+	if data["foreignkey"] != updatedKey {
+		t.Fatalf("expected post's ForeignKey to cascade to %s, got %v", updatedKey, data["foreignkey"])
+	}
+}
+
+func TestSchema_Update_Restrict(t *testing.T) {
+	schema, db, err := getUserSchema()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.PurgeAll()
+
+	schema.References[0].Constraints.Update = Restrict
+
+	if err = schema.Update(db, "users/2", nil); err != UpdateRestrictedError {
+		t.Fatalf("expected UpdateRestrictedError, got %v", err)
+	}
+
+	if !db.Has(userKey) {
+		t.Fatal("expected restricted update to leave the original user untouched")
+	}
+}
+
+func TestSchema_Update_NoAction(t *testing.T) {
 	schema, db, err := getUserSchema()
 	if err != nil {
 		t.Fatal(err)
 	}
 	defer db.PurgeAll()
 
-	// update the user schema key
-	// NOTE: in a realistic scenario you would also move the local file to a new location beforehand.
-	if err = schema.Update(db, "users/2", nil); err != nil && err != UpdateRestrictedError {
+	schema.References[0].Constraints.Update = NoAction
+	const updatedKey = "users/2"
+
+	if err = schema.Update(db, updatedKey, nil); err != nil {
 		t.Fatal(err)
 	}
+
+	var post Schema
+	if err = db.Read(postKey, &post); err != nil {
+		t.Fatal(err)
+	}
+
+	data, ok := post.Data.(map[interface{}]interface{})
+	if !ok {
+		t.Fatalf("expected post Data to decode as a map, got %T", post.Data)
+	}
+	if data["foreignkey"] != userKey {
+		t.Fatalf("expected NoAction not to touch the post's ForeignKey, got %v", data["foreignkey"])
+	}
+}
+
+// TestSchema_Update_TwoHopChain covers a cascade crossing two hops: users/1 -> posts/1 ->
+// comments/1, where comments/1 also denormalizes a ForeignKey back to the user.
+func TestSchema_Update_TwoHopChain(t *testing.T) {
+	const commentKey = "comments/1"
+
+	schema, db, err := getUserSchema()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.PurgeAll()
+
+	err = db.Write(postKey, Schema{
+		Key: postKey,
+		References: []*SchemaReference{
+			{
+				Key: commentKey,
+				Constraints: Constraints{
+					Update: Cascade,
+				},
+			},
+		},
+		Data: Post{
+			Id:         1,
+			Message:    "Hello World!",
+			ForeignKey: userKey,
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = db.Write(commentKey, Schema{
+		Key: commentKey,
+		Data: Post{
+			Id:         1,
+			Message:    "Nice post!",
+			ForeignKey: userKey,
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const updatedKey = "users/2"
+	if err = schema.Update(db, updatedKey, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	var comment Schema
+	if err = db.Read(commentKey, &comment); err != nil {
+		t.Fatal(err)
+	}
+
+	data, ok := comment.Data.(map[interface{}]interface{})
+	if !ok {
+		t.Fatalf("expected comment Data to decode as a map, got %T", comment.Data)
+	}
+	if data["foreignkey"] != updatedKey {
+		t.Fatalf("expected cascade to reach the second hop, got %v", data["foreignkey"])
+	}
+}
+
+// TestSchema_Update_CircularReference covers a reference cycle: users/1 -> posts/1 -> users/1.
+func TestSchema_Update_CircularReference(t *testing.T) {
+	schema, db, err := getUserSchema()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.PurgeAll()
+
+	err = db.Write(postKey, Schema{
+		Key: postKey,
+		References: []*SchemaReference{
+			{
+				Key: userKey,
+				Constraints: Constraints{
+					Update: Cascade,
+				},
+			},
+		},
+		Data: Post{
+			Id:         1,
+			Message:    "Hello World!",
+			ForeignKey: userKey,
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err = schema.Update(db, "users/2", nil); err != ErrCircularReference {
+		t.Fatalf("expected ErrCircularReference, got %v", err)
+	}
+
+	if !db.Has(userKey) {
+		t.Fatal("expected aborted cascade to leave the original user untouched")
+	}
 }
@@ -0,0 +1,232 @@
+package yamldb
+
+import (
+	"errors"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+var (
+	// ReadOnlyTxnError is returned when a write operation is attempted on a read transaction.
+	ReadOnlyTxnError = errors.New("yamldb: transaction is read-only")
+	// TxnClosedError is returned when a transaction is used after Commit or Abort.
+	TxnClosedError = errors.New("yamldb: transaction has already been committed or aborted")
+)
+
+// txnOp is a single staged mutation inside a Txn.
+type txnOp struct {
+	deleted bool
+	data    []byte
+}
+
+// Txn stages Write, WriteRaw, Delete, Purge and Update operations against a YamlDb and applies
+// them atomically on Commit, or discards them on Abort.
+//
+// At most one write transaction may be open on a YamlDb at a time. Any number of read
+// transactions may be open concurrently. Opening a write transaction blocks until every open
+// read transaction has closed, and no new read transaction may open while a write transaction
+// is in progress, so that cascading Schema constraint changes are observed atomically by
+// readers. Reads performed inside a write transaction observe its own pending, uncommitted
+// mutations (read-your-writes).
+type Txn struct {
+	db       *YamlDb
+	writable bool
+	done     bool
+	pending  map[string]*txnOp
+	order    []string
+}
+
+// NewReadTxn opens a read-only transaction. It blocks while a write transaction is open.
+func (db *YamlDb) NewReadTxn() *Txn {
+	db.txnMu.RLock()
+	return &Txn{db: db}
+}
+
+// NewWriteTxn opens a write transaction. It blocks until every open read transaction has closed,
+// and prevents new read transactions from opening until Commit or Abort is called.
+func (db *YamlDb) NewWriteTxn() *Txn {
+	db.txnMu.Lock()
+	return &Txn{db: db, writable: true, pending: make(map[string]*txnOp)}
+}
+
+// stage records op as the pending mutation for key, preserving first-write order for Commit.
+func (tx *Txn) stage(key string, op *txnOp) {
+	if _, ok := tx.pending[key]; !ok {
+		tx.order = append(tx.order, key)
+	}
+	tx.pending[key] = op
+}
+
+// Write stages a YAML-serializable struct to be written to the database on Commit.
+func (tx *Txn) Write(key string, s interface{}) error {
+	data, err := yaml.Marshal(s)
+	if err != nil {
+		return err
+	}
+	return tx.WriteRaw(key, data)
+}
+
+// WriteRaw stages a raw YAML resource to be written to the database on Commit.
+func (tx *Txn) WriteRaw(key string, data []byte) error {
+	if tx.done {
+		return TxnClosedError
+	}
+	if !tx.writable {
+		return ReadOnlyTxnError
+	}
+	tx.stage(key, &txnOp{data: data})
+	return nil
+}
+
+// Delete stages a resource to be removed from the database on Commit.
+func (tx *Txn) Delete(key string) error {
+	if tx.done {
+		return TxnClosedError
+	}
+	if !tx.writable {
+		return ReadOnlyTxnError
+	}
+	tx.stage(key, &txnOp{deleted: true})
+	return nil
+}
+
+// Purge stages removal of all resources that start with given prefix, including anything already
+// staged under that prefix earlier in this transaction.
+func (tx *Txn) Purge(prefix string) error {
+	if tx.done {
+		return TxnClosedError
+	}
+	if !tx.writable {
+		return ReadOnlyTxnError
+	}
+
+	cancel := make(chan struct{})
+	for key := range tx.db.Disk.KeysPrefix(prefix, cancel) {
+		tx.stage(key, &txnOp{deleted: true})
+	}
+
+	for key := range tx.pending {
+		if strings.HasPrefix(key, prefix) {
+			tx.stage(key, &txnOp{deleted: true})
+		}
+	}
+
+	return nil
+}
+
+// Update stages an update to a resource, read through the transaction so updateFunc sees any
+// pending writes made earlier in the same transaction.
+func (tx *Txn) Update(key string, t interface{}, updateFunc func(s interface{})) error {
+	if err := tx.Read(key, t); err != nil {
+		return err
+	}
+
+	updateFunc(t)
+
+	return tx.Write(key, t)
+}
+
+func (tx *Txn) read(key string, out interface{}, strict bool) error {
+	if tx.done {
+		return TxnClosedError
+	}
+
+	if op, ok := tx.pending[key]; ok {
+		if op.deleted {
+			return os.ErrNotExist
+		}
+		if strict {
+			return yaml.UnmarshalStrict(op.data, out)
+		}
+		return yaml.Unmarshal(op.data, out)
+	}
+
+	return tx.db.read(key, out, strict)
+}
+
+// Read unmarshals a resource into given struct, preferring this transaction's own pending writes.
+func (tx *Txn) Read(key string, out interface{}) error {
+	return tx.read(key, out, false)
+}
+
+// ReadStrict strictly unmarshals a resource into given struct, preferring this transaction's own
+// pending writes.
+func (tx *Txn) ReadStrict(key string, out interface{}) error {
+	return tx.read(key, out, true)
+}
+
+// ReadRaw reads a raw YAML resource, preferring this transaction's own pending writes.
+func (tx *Txn) ReadRaw(key string) ([]byte, error) {
+	if tx.done {
+		return nil, TxnClosedError
+	}
+
+	if op, ok := tx.pending[key]; ok {
+		if op.deleted {
+			return nil, os.ErrNotExist
+		}
+		return op.data, nil
+	}
+
+	return tx.db.ReadRaw(key)
+}
+
+// Has returns whether the database stores a value for provided key, taking this transaction's
+// own pending writes into account.
+func (tx *Txn) Has(key string) bool {
+	if tx.done {
+		return false
+	}
+
+	if op, ok := tx.pending[key]; ok {
+		return !op.deleted
+	}
+
+	return tx.db.Has(key)
+}
+
+// Commit applies all pending writes and deletes to the database in the order they were staged,
+// then releases the transaction's lock. A read transaction has no pending mutations, so Commit
+// simply releases its lock.
+//
+// When the underlying YamlDb was created with DiskOptions.TempDir set, every payload is written
+// to that temp directory before any file is renamed into place (see applyAtomically), so a
+// failure while staging a payload leaves every key untouched rather than a half-applied commit.
+// Because no new read transaction can open until this one releases txnMu, readers using Txn
+// never observe the commit partway through either.
+func (tx *Txn) Commit() error {
+	if tx.done {
+		return TxnClosedError
+	}
+	tx.done = true
+
+	if !tx.writable {
+		tx.db.txnMu.RUnlock()
+		return nil
+	}
+	defer tx.db.txnMu.Unlock()
+
+	ops := make([]applyOp, len(tx.order))
+	for i, key := range tx.order {
+		op := tx.pending[key]
+		ops[i] = applyOp{key: key, deleted: op.deleted, data: op.data}
+	}
+
+	return tx.db.applyAtomically(ops)
+}
+
+// Abort discards all pending mutations and releases the transaction's lock.
+func (tx *Txn) Abort() {
+	if tx.done {
+		return
+	}
+	tx.done = true
+
+	if tx.writable {
+		tx.db.txnMu.Unlock()
+	} else {
+		tx.db.txnMu.RUnlock()
+	}
+}
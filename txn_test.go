@@ -0,0 +1,159 @@
+package yamldb
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTxn_WriteCommit(t *testing.T) {
+	db := newYamlDb()
+	defer db.PurgeAll()
+
+	tx := db.NewWriteTxn()
+
+	if err := tx.Write("foo", Mock{Amount: 123}); err != nil {
+		t.Fatal(err)
+	}
+
+	if db.Has("foo") {
+		t.Fatal("expected pending write not to be visible outside the transaction before Commit")
+	}
+
+	if err := tx.Commit(); err != nil {
+		t.Fatal(err)
+	}
+
+	var m Mock
+	if err := db.Read("foo", &m); err != nil {
+		t.Fatal(err)
+	}
+	if m.Amount != 123 {
+		t.FailNow()
+	}
+}
+
+func TestTxn_WriteCommit_WithTempDir(t *testing.T) {
+	db := New(&DiskOptions{
+		BasePath:        basePath,
+		AppendExtension: true,
+		TempDir:         basePath + "/.tmp",
+	})
+	defer db.PurgeAll()
+
+	if err := db.WriteRaw("old", []byte("bar")); err != nil {
+		t.Fatal(err)
+	}
+
+	tx := db.NewWriteTxn()
+	if err := tx.Write("foo", Mock{Amount: 123}); err != nil {
+		t.Fatal(err)
+	}
+	if err := tx.Delete("old"); err != nil {
+		t.Fatal(err)
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatal(err)
+	}
+
+	var m Mock
+	if err := db.Read("foo", &m); err != nil {
+		t.Fatal(err)
+	}
+	if m.Amount != 123 {
+		t.FailNow()
+	}
+	if db.Has("old") {
+		t.Fatal("expected staged delete to have been applied")
+	}
+}
+
+func TestTxn_Abort(t *testing.T) {
+	db := newYamlDb()
+	defer db.PurgeAll()
+
+	tx := db.NewWriteTxn()
+
+	if err := tx.Write("foo", Mock{Amount: 123}); err != nil {
+		t.Fatal(err)
+	}
+
+	tx.Abort()
+
+	if db.Has("foo") {
+		t.Fatal("expected aborted write not to be applied")
+	}
+}
+
+func TestTxn_ReadYourWrites(t *testing.T) {
+	db := newYamlDb()
+	defer db.PurgeAll()
+
+	tx := db.NewWriteTxn()
+	defer tx.Abort()
+
+	if err := tx.Write("foo", Mock{Amount: 123}); err != nil {
+		t.Fatal(err)
+	}
+
+	var m Mock
+	if err := tx.Read("foo", &m); err != nil {
+		t.Fatal(err)
+	}
+	if m.Amount != 123 {
+		t.FailNow()
+	}
+}
+
+func TestTxn_WriteBlocksWhileReadOpen(t *testing.T) {
+	db := newYamlDb()
+	defer db.PurgeAll()
+
+	rtx := db.NewReadTxn()
+
+	writeOpened := make(chan struct{})
+	go func() {
+		wtx := db.NewWriteTxn()
+		close(writeOpened)
+		wtx.Abort()
+	}()
+
+	select {
+	case <-writeOpened:
+		t.Fatal("expected write transaction to block while a read transaction is open")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	rtx.Commit()
+
+	select {
+	case <-writeOpened:
+	case <-time.After(time.Second):
+		t.Fatal("expected write transaction to open once the read transaction closed")
+	}
+}
+
+func TestTxn_ReadOnlyWriteFails(t *testing.T) {
+	db := newYamlDb()
+	defer db.PurgeAll()
+
+	tx := db.NewReadTxn()
+	defer tx.Commit()
+
+	if err := tx.Write("foo", Mock{}); err != ReadOnlyTxnError {
+		t.Fatalf("expected ReadOnlyTxnError, got %v", err)
+	}
+}
+
+func TestTxn_UseAfterCommitFails(t *testing.T) {
+	db := newYamlDb()
+	defer db.PurgeAll()
+
+	tx := db.NewWriteTxn()
+	if err := tx.Commit(); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := tx.Write("foo", Mock{}); err != TxnClosedError {
+		t.Fatalf("expected TxnClosedError, got %v", err)
+	}
+}
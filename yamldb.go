@@ -4,9 +4,12 @@ package yamldb
 import (
 	"github.com/peterbourgon/diskv/v3"
 	"gopkg.in/yaml.v2"
+	"io"
+	"io/ioutil"
 	"os"
 	"path"
 	"strings"
+	"sync"
 )
 
 const extension = ".yaml"
@@ -15,6 +18,26 @@ type YamlDb struct {
 	// Disk exposes the underlying diskv disk.
 	// Set and/or get this field only for advanced usage.
 	Disk *diskv.Diskv
+
+	// txnMu guards transactional access to Disk: read transactions take the read lock,
+	// the (at most one) open write transaction takes the write lock.
+	txnMu sync.RWMutex
+
+	// sortLess is the OrderFunc keys are sorted by when a deterministic key order is needed
+	// outside of Disk.Index, e.g. when flushing a CacheDb. Defaults to OrderAlphabetically.
+	sortLess OrderFunc
+
+	// migrations holds registered migration functions, keyed by schema type and then by the
+	// Schema.Version they migrate away from. See RegisterMigration.
+	migrations map[string]map[int]MigrationFunc
+}
+
+// sortOrder returns the OrderFunc to use when a deterministic key order is needed.
+func (db *YamlDb) sortOrder() OrderFunc {
+	if db.sortLess != nil {
+		return db.sortLess
+	}
+	return OrderAlphabetically
 }
 
 type DiskPermissions struct {
@@ -78,9 +101,16 @@ func New(options *DiskOptions) *YamlDb {
 			diskOptions.IndexLess = diskv.LessFunction(f)
 		}
 	}
-	return &YamlDb{
+	db := &YamlDb{
 		Disk: diskv.New(diskOptions),
 	}
+	if options.SortKeys {
+		db.sortLess = OrderAlphabetically
+		if options.SortOrderFunc != nil {
+			db.sortLess = options.SortOrderFunc
+		}
+	}
+	return db
 }
 
 // Write writes a YAML-serializable struct to the database.
@@ -223,6 +253,120 @@ func (db *YamlDb) IterateSerialized(prefix string, out interface{}, callback fun
 	})
 }
 
+// nopWriteCloser adapts an io.Writer to an io.WriteCloser whose Close does nothing, for use with
+// an *os.File whose own Close is called separately once the file is fully staged.
+type nopWriteCloser struct{ io.Writer }
+
+func (nopWriteCloser) Close() error { return nil }
+
+// applyOp is a single staged write-or-delete, ready to be applied to disk. Batch and Txn both
+// stage their pending mutations into a slice of these and hand them to applyAtomically, so they
+// share the same crash-safety story.
+type applyOp struct {
+	key     string
+	deleted bool
+	data    []byte
+}
+
+// applyAtomically applies ops in order. When Disk.TempDir is configured, every write's payload is
+// first written to a temp file there - compressed the same way a normal Write would be - and only
+// once every payload has landed on disk are the temp files renamed into place, one after another.
+// That way, a failure while staging any payload (e.g. a slow disk, an I/O error) leaves every key
+// at its previous value, rather than a batch or transaction half-applied partway through.
+//
+// Without Disk.TempDir, diskv has no staging area to write through, so ops are just applied one
+// at a time via the normal Write/Delete path, same as before.
+func (db *YamlDb) applyAtomically(ops []applyOp) error {
+	if db.Disk.TempDir == "" {
+		for _, op := range ops {
+			if op.deleted {
+				if err := db.Delete(op.key); err != nil {
+					return err
+				}
+				continue
+			}
+			if err := db.WriteRaw(op.key, op.data); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if err := os.MkdirAll(db.Disk.TempDir, db.Disk.PathPerm); err != nil {
+		return err
+	}
+
+	tempPaths := make(map[string]string, len(ops))
+	cleanup := func() {
+		for _, tempPath := range tempPaths {
+			os.Remove(tempPath) // error deliberately ignored, best-effort cleanup
+		}
+	}
+
+	for _, op := range ops {
+		if op.deleted {
+			continue
+		}
+
+		f, err := ioutil.TempFile(db.Disk.TempDir, "")
+		if err != nil {
+			cleanup()
+			return err
+		}
+
+		// w wraps f when compression is configured, so its Close flushes any compressed
+		// trailer; f itself is always closed separately, once, below.
+		var w io.WriteCloser = nopWriteCloser{f}
+		if db.Disk.Compression != nil {
+			w, err = db.Disk.Compression.Writer(f)
+			if err != nil {
+				f.Close()
+				os.Remove(f.Name())
+				cleanup()
+				return err
+			}
+		}
+
+		_, writeErr := w.Write(op.data)
+		closeErr := w.Close()
+		if writeErr == nil {
+			writeErr = closeErr
+		}
+		if writeErr != nil {
+			f.Close()
+			os.Remove(f.Name())
+			cleanup()
+			return writeErr
+		}
+
+		if err := f.Close(); err != nil {
+			os.Remove(f.Name())
+			cleanup()
+			return err
+		}
+
+		tempPaths[op.key] = f.Name()
+	}
+
+	for _, op := range ops {
+		if op.deleted {
+			if err := db.Delete(op.key); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if err := db.Disk.Import(tempPaths[op.key], op.key, true); err != nil {
+			return err
+		}
+		if db.Disk.Index != nil {
+			db.Disk.Index.Insert(op.key)
+		}
+	}
+
+	return nil
+}
+
 // GetOrderedKeys returns all keys - or those with given prefix - in order when SortKeys is enabled.
 // Specify how many keys should be fetched from the underlying Index at a time through the chunks parameter.
 // You can also start querying from a specific key.